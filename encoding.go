@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoding selects how DataGenerator serializes the records for one request.
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "json"      // default: one JSON value (object, or array when -records > 1)
+	EncodingNDJSON   Encoding = "ndjson"    // one JSON object per line
+	EncodingESBulk   Encoding = "es-bulk"   // Elasticsearch/OpenObserve _bulk action+document line pairs
+	EncodingLoki     Encoding = "loki"      // Loki push API {streams:[...]} shape
+	EncodingOTLPLogs Encoding = "otlp-logs" // OTLP JSON logs envelope
+)
+
+// ContentType returns the HTTP Content-Type to send alongside a payload
+// produced in this encoding.
+func (e Encoding) ContentType() string {
+	switch e {
+	case EncodingNDJSON, EncodingESBulk:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// GenerateEncoded produces the wire payload for one request according to
+// dg.Encoding, building on the same per-record generation GenerateData uses.
+// All randomness is drawn from rng so a run is reproducible given the same -seed.
+func (dg *DataGenerator) GenerateEncoded(rng *rand.Rand) ([]byte, error) {
+	switch Encoding(dg.Encoding) {
+	case "", EncodingJSON:
+		return json.Marshal(dg.GenerateData(rng))
+	case EncodingNDJSON:
+		return dg.generateNDJSON(rng)
+	case EncodingESBulk:
+		return dg.generateESBulk(rng)
+	case EncodingLoki:
+		return dg.generateLoki(rng)
+	case EncodingOTLPLogs:
+		return dg.generateOTLPLogs(rng)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", dg.Encoding)
+	}
+}
+
+// records normalizes GenerateData's output (a single record, or a slice when
+// RecordsPerReq > 1) into a slice, so the encoders below only handle one shape.
+func (dg *DataGenerator) records(rng *rand.Rand) []map[string]interface{} {
+	switch v := dg.GenerateData(rng).(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	case []map[string]interface{}:
+		return v
+	default:
+		return nil
+	}
+}
+
+func (dg *DataGenerator) generateNDJSON(rng *rand.Rand) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, r := range dg.records(rng) {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// generateESBulk emits alternating action/document lines as the Elasticsearch,
+// OpenObserve, and compatible _bulk APIs expect.
+func (dg *DataGenerator) generateESBulk(rng *rand.Rand) ([]byte, error) {
+	index := dg.IndexName
+	if index == "" {
+		index = "data-generator"
+	}
+	index = expandIndexTemplate(index)
+
+	var buf bytes.Buffer
+	for _, r := range dg.records(rng) {
+		action, err := json.Marshal(map[string]interface{}{
+			"index": map[string]interface{}{"_index": index},
+		})
+		if err != nil {
+			return nil, err
+		}
+		doc, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// expandIndexTemplate substitutes the {date} placeholder with today's date,
+// the one piece of templating most bulk-ingest naming schemes need.
+func expandIndexTemplate(tmpl string) string {
+	return strings.ReplaceAll(tmpl, "{date}", time.Now().Format("2006.01.02"))
+}
+
+// generateLoki builds the Loki push API shape: fields whose schema definition
+// is tagged `label: true` become stream labels, the rest are serialized as
+// the log line.
+func (dg *DataGenerator) generateLoki(rng *rand.Rand) ([]byte, error) {
+	labels := make(map[string]string)
+	values := make([][2]string, 0)
+
+	for _, r := range dg.records(rng) {
+		line := make(map[string]interface{})
+		for k, v := range r {
+			if dg.Schema != nil && dg.Schema.Labels[k] {
+				labels[k] = fmt.Sprintf("%v", v)
+				continue
+			}
+			line[k] = v
+		}
+		lineBytes, err := json.Marshal(line)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, [2]string{strconv.FormatInt(time.Now().UnixNano(), 10), string(lineBytes)})
+	}
+
+	payload := map[string]interface{}{
+		"streams": []interface{}{
+			map[string]interface{}{
+				"stream": labels,
+				"values": values,
+			},
+		},
+	}
+	return json.Marshal(payload)
+}
+
+// generateOTLPLogs builds an OTLP JSON logs envelope with one resourceLogs
+// entry and one logRecord per generated record.
+func (dg *DataGenerator) generateOTLPLogs(rng *rand.Rand) ([]byte, error) {
+	logRecords := make([]interface{}, 0)
+	for _, r := range dg.records(rng) {
+		body, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		logRecords = append(logRecords, map[string]interface{}{
+			"timeUnixNano": strconv.FormatInt(time.Now().UnixNano(), 10),
+			"body":         map[string]interface{}{"stringValue": string(body)},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{
+							"key":   "service.name",
+							"value": map[string]interface{}{"stringValue": "data-generator"},
+						},
+					},
+				},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{"logRecords": logRecords},
+				},
+			},
+		},
+	}
+	return json.Marshal(payload)
+}
@@ -0,0 +1,144 @@
+package main
+
+import "math"
+
+// Histogram-wide bucketing parameters. Values are clamped to
+// [histMinNanos, histMaxNanos] and bucketed on a logarithmic scale with
+// enough subdivisions per decade to keep ~3 significant digits of
+// precision, the same tradeoff HdrHistogram makes. Recording a sample is
+// O(1): a single log10 plus a slice index.
+const (
+	histMinNanos         = float64(1000)  // 1µs floor
+	histMaxNanos         = float64(60e9)  // 60s ceiling
+	histBucketsPerDecade = 1000           // ~3 significant digits
+)
+
+var histBucketCount = int(math.Ceil(math.Log10(histMaxNanos/histMinNanos)*histBucketsPerDecade)) + 1
+
+// Histogram is a fixed-bucket latency histogram. Each worker keeps its own
+// instance during a run (no shared state, no locking); histograms are
+// merged bucket-wise only once, when the final report is produced.
+type Histogram struct {
+	buckets []int64
+	count   int64
+	sum     float64 // nanoseconds
+	sumSq   float64 // nanoseconds^2, for stddev
+	min     float64
+	max     float64
+}
+
+// NewHistogram returns an empty histogram ready to record samples.
+func NewHistogram() *Histogram {
+	return &Histogram{
+		buckets: make([]int64, histBucketCount),
+		min:     math.MaxFloat64,
+	}
+}
+
+// Record adds one latency sample, in nanoseconds.
+func (h *Histogram) Record(nanos float64) {
+	if nanos < histMinNanos {
+		nanos = histMinNanos
+	}
+	if nanos > histMaxNanos {
+		nanos = histMaxNanos
+	}
+
+	idx := int(math.Log10(nanos/histMinNanos) * histBucketsPerDecade)
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(h.buckets) {
+		idx = len(h.buckets) - 1
+	}
+
+	h.buckets[idx]++
+	h.count++
+	h.sum += nanos
+	h.sumSq += nanos * nanos
+	if nanos < h.min {
+		h.min = nanos
+	}
+	if nanos > h.max {
+		h.max = nanos
+	}
+}
+
+// Merge folds another histogram's buckets and stats into this one.
+func (h *Histogram) Merge(other *Histogram) {
+	for i, c := range other.buckets {
+		h.buckets[i] += c
+	}
+	h.count += other.count
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+	if other.count == 0 {
+		return
+	}
+	if other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+// Percentile returns the approximate latency, in nanoseconds, at or below
+// which p percent (0-100) of recorded samples fall.
+func (h *Histogram) Percentile(p float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(p / 100 * float64(h.count)))
+	if target < 1 {
+		target = 1
+	}
+
+	var cum int64
+	for i, c := range h.buckets {
+		cum += c
+		if cum >= target {
+			return histMinNanos * math.Pow(10, float64(i)/histBucketsPerDecade)
+		}
+	}
+	return h.max
+}
+
+// Mean returns the average recorded latency, in nanoseconds.
+func (h *Histogram) Mean() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / float64(h.count)
+}
+
+// StdDev returns the standard deviation of recorded latencies, in nanoseconds.
+func (h *Histogram) StdDev() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	mean := h.Mean()
+	variance := h.sumSq/float64(h.count) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return math.Sqrt(variance)
+}
+
+// Min returns the smallest recorded latency, in nanoseconds, or 0 if empty.
+func (h *Histogram) Min() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.min
+}
+
+// Max returns the largest recorded latency, in nanoseconds.
+func (h *Histogram) Max() float64 {
+	return h.max
+}
+
+// Count returns the number of recorded samples.
+func (h *Histogram) Count() int64 {
+	return h.count
+}
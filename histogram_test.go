@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHistogramPercentile(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 100; i++ {
+		h.Record(float64(i) * 1e6) // 1ms .. 100ms
+	}
+
+	if p50 := h.Percentile(50); math.Abs(p50-50e6)/50e6 > 0.05 {
+		t.Errorf("Percentile(50) = %v, want ~50ms (within 5%%)", p50)
+	}
+	if p100 := h.Percentile(100); p100 != h.Max() {
+		t.Errorf("Percentile(100) = %v, want Max() %v", p100, h.Max())
+	}
+}
+
+func TestHistogramPercentileEmpty(t *testing.T) {
+	h := NewHistogram()
+	if got := h.Percentile(50); got != 0 {
+		t.Errorf("Percentile(50) on empty histogram = %v, want 0", got)
+	}
+}
+
+func TestHistogramStdDevIdenticalSamples(t *testing.T) {
+	h := NewHistogram()
+	for i := 0; i < 4; i++ {
+		h.Record(10e6)
+	}
+	if got := h.StdDev(); got != 0 {
+		t.Errorf("StdDev of identical samples = %v, want 0", got)
+	}
+}
+
+func TestHistogramStdDevVariedSamples(t *testing.T) {
+	h := NewHistogram()
+	h.Record(1e6)
+	h.Record(1e6)
+	h.Record(1e9)
+	if got := h.StdDev(); got <= 0 {
+		t.Errorf("StdDev of varied samples = %v, want > 0", got)
+	}
+}
+
+func TestHistogramStdDevEmpty(t *testing.T) {
+	h := NewHistogram()
+	if got := h.StdDev(); got != 0 {
+		t.Errorf("StdDev on empty histogram = %v, want 0", got)
+	}
+}
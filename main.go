@@ -2,17 +2,21 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,7 +25,6 @@ type HTTPClient struct {
 	URL      string
 	Username string
 	Password string
-	Data     interface{}
 	Headers  map[string]string
 }
 
@@ -54,15 +57,17 @@ type DataGenerator struct {
 	FieldCount    int
 	RecordsPerReq int
 	EnableBody    bool
+	Schema        *Schema // when set, records are built from the schema instead of the fixed log shape
+	Encoding      string  // see Encoding in encoding.go; "" behaves like EncodingJSON
+	IndexName     string  // _index name template for EncodingESBulk, e.g. "logs-{date}"
 }
 
 // NewHTTPClient creates a new HTTP client instance
-func NewHTTPClient(url, username, password string, data interface{}) *HTTPClient {
+func NewHTTPClient(url, username, password string) *HTTPClient {
 	return &HTTPClient{
 		URL:      url,
 		Username: username,
 		Password: password,
-		Data:     data,
 		Headers:  make(map[string]string),
 	}
 }
@@ -73,33 +78,33 @@ func (c *HTTPClient) AddHeader(key, value string) {
 }
 
 // generateRandomString generates a random string of specified length
-func generateRandomString(length int) string {
+func generateRandomString(rng *rand.Rand, length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, length)
 	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+		b[i] = charset[rng.Intn(len(charset))]
 	}
 	return string(b)
 }
 
 // generateRandomIP generates a random IP address
-func generateRandomIP() string {
-	return fmt.Sprintf("%d.%d.%d.%d", rand.Intn(256), rand.Intn(256), rand.Intn(256), rand.Intn(256))
+func generateRandomIP(rng *rand.Rand) string {
+	return fmt.Sprintf("%d.%d.%d.%d", rng.Intn(256), rng.Intn(256), rng.Intn(256), rng.Intn(256))
 }
 
 // generateRandomPath generates a random URL path
-func generateRandomPath() string {
+func generateRandomPath(rng *rand.Rand) string {
 	paths := []string{
 		"/api/users", "/api/posts", "/api/comments", "/api/products",
 		"/api/orders", "/api/categories", "/api/search", "/api/analytics",
 		"/api/reports", "/api/settings", "/api/profile", "/api/dashboard",
 		"/api/notifications", "/api/messages", "/api/files", "/api/upload",
 	}
-	return paths[rand.Intn(len(paths))]
+	return paths[rng.Intn(len(paths))]
 }
 
 // generateRandomUserAgent generates a random user agent string
-func generateRandomUserAgent() string {
+func generateRandomUserAgent(rng *rand.Rand) string {
 	userAgents := []string{
 		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
 		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36",
@@ -107,21 +112,21 @@ func generateRandomUserAgent() string {
 		"Mozilla/5.0 (iPhone; CPU iPhone OS 14_7_1 like Mac OS X) AppleWebKit/605.1.15",
 		"Mozilla/5.0 (Android 11; Mobile; rv:68.0) Gecko/68.0 Firefox/68.0",
 	}
-	return userAgents[rand.Intn(len(userAgents))]
+	return userAgents[rng.Intn(len(userAgents))]
 }
 
 // generateRandomReferer generates a random referer URL
-func generateRandomReferer() string {
+func generateRandomReferer(rng *rand.Rand) string {
 	domains := []string{
 		"https://www.google.com", "https://www.facebook.com", "https://www.twitter.com",
 		"https://www.linkedin.com", "https://www.github.com", "https://www.stackoverflow.com",
 		"https://www.reddit.com", "https://www.youtube.com", "https://www.amazon.com",
 	}
-	return domains[rand.Intn(len(domains))]
+	return domains[rng.Intn(len(domains))]
 }
 
 // generateRandomBody generates random binary data and returns it as base64 encoded string
-func generateRandomBody(sizeKB int) string {
+func generateRandomBody(rng *rand.Rand, sizeKB int) string {
 	if sizeKB == 0 {
 		return ""
 	}
@@ -132,7 +137,7 @@ func generateRandomBody(sizeKB int) string {
 	// Generate random binary data
 	randomData := make([]byte, sizeBytes)
 	for i := range randomData {
-		randomData[i] = byte(rand.Intn(256))
+		randomData[i] = byte(rng.Intn(256))
 	}
 
 	// Encode to base64
@@ -140,27 +145,27 @@ func generateRandomBody(sizeKB int) string {
 }
 
 // generateLogRecord generates a single log record
-func generateLogRecord(enableBody bool) LogRecord {
+func generateLogRecord(rng *rand.Rand, enableBody bool) LogRecord {
 	now := time.Now()
 	log := LogRecord{
 		Timestamp:   now.Format(time.RFC3339),
-		IP:          generateRandomIP(),
-		Method:      []string{"GET", "POST", "PUT", "DELETE", "PATCH"}[rand.Intn(5)],
-		Path:        generateRandomPath(),
-		Status:      []int{200, 201, 400, 401, 403, 404, 500}[rand.Intn(7)],
-		Bytes:       rand.Intn(10000) + 100,
-		UserAgent:   generateRandomUserAgent(),
-		Referer:     generateRandomReferer(),
-		RequestTime: rand.Float64()*2.0 + 0.1, // 0.1 to 2.1 seconds
-		RemoteAddr:  generateRandomIP(),
-		ServerName:  "nginx-server-" + generateRandomString(4),
+		IP:          generateRandomIP(rng),
+		Method:      []string{"GET", "POST", "PUT", "DELETE", "PATCH"}[rng.Intn(5)],
+		Path:        generateRandomPath(rng),
+		Status:      []int{200, 201, 400, 401, 403, 404, 500}[rng.Intn(7)],
+		Bytes:       rng.Intn(10000) + 100,
+		UserAgent:   generateRandomUserAgent(rng),
+		Referer:     generateRandomReferer(rng),
+		RequestTime: rng.Float64()*2.0 + 0.1, // 0.1 to 2.1 seconds
+		RemoteAddr:  generateRandomIP(rng),
+		ServerName:  "nginx-server-" + generateRandomString(rng, 4),
 	}
 
 	// Only add body field if enabled, with random size between 1KB-200KB
 	if enableBody {
 		// Generate random size between 1KB and 200KB
-		bodySizeKB := rand.Intn(200)
-		body := generateRandomBody(bodySizeKB)
+		bodySizeKB := rng.Intn(200)
+		body := generateRandomBody(rng, bodySizeKB)
 		log.Body = &body
 	}
 
@@ -168,10 +173,10 @@ func generateLogRecord(enableBody bool) LogRecord {
 }
 
 // generateRandomData generates random JSON data with specified number of fields
-func generateRandomData(fieldCount int, enableBody bool) map[string]interface{} {
+func generateRandomData(rng *rand.Rand, fieldCount int, enableBody bool) map[string]interface{} {
 	data := make(map[string]interface{})
 	// Generate log record as the base data
-	log := generateLogRecord(enableBody)
+	log := generateLogRecord(rng, enableBody)
 	// Add log record to data as a string
 	if logBytes, err := json.Marshal(log); err == nil {
 		data["message"] = string(logBytes)
@@ -179,7 +184,7 @@ func generateRandomData(fieldCount int, enableBody bool) map[string]interface{}
 
 	// Always include timestamp
 	data["timestamp"] = time.Now().Format(time.RFC3339)
-	data["request_id"] = generateRandomString(16)
+	data["request_id"] = generateRandomString(rng, 16)
 
 	// Generate additional random fields (all single values, no arrays)
 	fieldNames := []string{"user_id", "session_id", "action", "resource", "category", "priority", "level", "source", "target", "metadata"}
@@ -188,50 +193,51 @@ func generateRandomData(fieldCount int, enableBody bool) map[string]interface{}
 		fieldName := fieldNames[i%len(fieldNames)] + strconv.Itoa(i)
 
 		// Randomly choose between string, number, and boolean (no arrays)
-		fieldType := rand.Intn(3) // 0=string, 1=number, 2=boolean
+		fieldType := rng.Intn(3) // 0=string, 1=number, 2=boolean
 
 		switch fieldType {
 		case 0: // string
-			data[fieldName] = generateRandomString(rand.Intn(20) + 5)
+			data[fieldName] = generateRandomString(rng, rng.Intn(20)+5)
 		case 1: // number
-			data[fieldName] = rand.Intn(10000)
+			data[fieldName] = rng.Intn(10000)
 		case 2: // boolean
-			data[fieldName] = rand.Intn(2) == 1
+			data[fieldName] = rng.Intn(2) == 1
 		}
 	}
 
 	return data
 }
 
-// GenerateData generates JSON data based on the generator configuration
-func (dg *DataGenerator) GenerateData() interface{} {
-	if dg.RecordsPerReq == 1 {
-		return generateRandomData(dg.FieldCount, dg.EnableBody)
-	} else {
-		// Generate multiple records
-		records := make([]map[string]interface{}, dg.RecordsPerReq)
-		for i := 0; i < dg.RecordsPerReq; i++ {
-			records[i] = generateRandomData(dg.FieldCount, dg.EnableBody)
+// GenerateData generates JSON data based on the generator configuration, drawing
+// all randomness from rng so a run is reproducible given the same -seed.
+func (dg *DataGenerator) GenerateData(rng *rand.Rand) interface{} {
+	generateOne := func() map[string]interface{} {
+		if dg.Schema != nil {
+			return dg.Schema.Generate(rng)
 		}
-		return records
+		return generateRandomData(rng, dg.FieldCount, dg.EnableBody)
 	}
-}
 
-// PostJSON sends a POST request with JSON data and basic auth
-func (c *HTTPClient) PostJSON() Response {
-	start := time.Now()
+	if dg.RecordsPerReq == 1 {
+		return generateOne()
+	}
 
-	// Marshal JSON data
-	jsonData, err := json.Marshal(c.Data)
-	if err != nil {
-		return Response{
-			Error:    fmt.Errorf("failed to marshal JSON: %v", err),
-			Duration: time.Since(start),
-		}
+	// Generate multiple records
+	records := make([]map[string]interface{}, dg.RecordsPerReq)
+	for i := 0; i < dg.RecordsPerReq; i++ {
+		records[i] = generateOne()
 	}
+	return records
+}
 
-	// Create request
-	req, err := http.NewRequest("POST", c.URL, bytes.NewBuffer(jsonData))
+// postPayload sends payload as the body of a POST request to url, applying
+// basic auth, the given Content-Type, and custom headers. payload is already
+// encoded by the caller (httpSink) and must not be re-marshaled. ctx governs
+// cancellation and deadline.
+func postPayload(ctx context.Context, httpClient *http.Client, url, username, password, contentType string, headers map[string]string, payload []byte) Response {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
 	if err != nil {
 		return Response{
 			Error:    fmt.Errorf("failed to create request: %v", err),
@@ -240,26 +246,24 @@ func (c *HTTPClient) PostJSON() Response {
 	}
 
 	// Set basic auth
-	if c.Username != "" || c.Password != "" {
-		auth := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+	if username != "" || password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
 		req.Header.Set("Authorization", "Basic "+auth)
 	}
 
 	// Set default headers
-	req.Header.Set("Content-Type", "application/json")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
 
 	// Set custom headers
-	for key, value := range c.Headers {
+	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
 
-	// Send request
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return Response{
 			Error:    fmt.Errorf("failed to send request: %v", err),
@@ -285,113 +289,339 @@ func (c *HTTPClient) PostJSON() Response {
 	}
 }
 
-// RunMultiple executes the POST request multiple times with optional concurrent execution
-func (c *HTTPClient) RunMultiple(times int, threads int, generator *DataGenerator) {
-	fmt.Printf("Running HTTP POST request %d times to: %s\n", times, c.URL)
-	if threads > 1 {
-		fmt.Printf("Using %d concurrent threads\n", threads)
+// LoadTestConfig controls how RunMultiple paces and measures a run. When
+// Duration is zero, the run stops after exactly Times requests (the
+// original "burst" mode); otherwise it runs for the given wall-clock
+// duration instead. When Rate is zero, requests are dispatched as fast as
+// the workers can consume them; otherwise a ticker paces dispatch to a
+// sustained Rate requests/sec.
+type LoadTestConfig struct {
+	Times          int
+	Threads        int
+	Rate           int
+	Duration       time.Duration
+	Warmup         time.Duration
+	ReportInterval time.Duration
+	Seed           int64 // base seed for per-worker RNGs, see RunMultiple
+}
+
+// loadStats accumulates the results of a run: one histogram per worker
+// (merged only at report time) plus atomic request/status counters that are
+// safe to read concurrently while the run is still in flight.
+type loadStats struct {
+	workerHists []*Histogram
+	sent        int64
+	success     int64
+	failed      int64
+	statusMu    sync.Mutex
+	statusCount map[int]int64
+}
+
+func newLoadStats(threads int) *loadStats {
+	hists := make([]*Histogram, threads)
+	for i := range hists {
+		hists[i] = NewHistogram()
+	}
+	return &loadStats{workerHists: hists, statusCount: make(map[int]int64)}
+}
+
+func (s *loadStats) recordStatus(code int) {
+	s.statusMu.Lock()
+	s.statusCount[code]++
+	s.statusMu.Unlock()
+}
+
+func (s *loadStats) mergedHistogram() *Histogram {
+	merged := NewHistogram()
+	for _, h := range s.workerHists {
+		merged.Merge(h)
+	}
+	return merged
+}
+
+// dispatch feeds workChans in round-robin (request 0 to workChans[0], request
+// 1 to workChans[1], …, request len(workChans) back to workChans[0], …) while
+// cond() is true, pacing itself to rate requests/sec via a ticker when rate >
+// 0. Round-robin assignment happens on this single goroutine rather than
+// workers racing to pull from a shared channel, so which worker generates
+// which request index is deterministic across runs given the same thread
+// count. It stops early if ctx is canceled (e.g. by SIGINT), leaving
+// already-dispatched work to drain normally.
+func dispatch(ctx context.Context, workChans []chan<- struct{}, rate int, cond func() bool) {
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i := 0; cond(); i++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case workChans[i%len(workChans)] <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ObservabilityConfig controls how a run's results are exported beyond the
+// console: live Prometheus collectors and a machine-readable summary file.
+// Metrics may be nil and SummaryOut may be empty; both are optional.
+type ObservabilityConfig struct {
+	Metrics       *PromMetrics
+	SummaryFormat string
+	SummaryOut    string
+}
+
+// RunMultiple runs the configured load: a token-bucket ticker (when Rate is
+// set) feeds worker goroutines round-robin, which encode a record via
+// generator (or reuse staticData) and hand it to sink (with per-request
+// timeout and retry per reqCfg), recording latencies into per-worker
+// histograms. Each worker seeds its own *rand.Rand from cfg.Seed, and
+// round-robin dispatch means worker i always generates request indices i,
+// i+Threads, i+2*Threads, …, so two runs with the same Seed, Threads, and
+// Times/Duration produce the same records in the same order regardless of
+// scheduling. An optional periodic report can be printed every
+// ReportInterval while the run is still going. Canceling ctx (e.g. via
+// SIGINT) stops dispatch early; in-flight requests are canceled and the
+// partial results are still summarized.
+func RunMultiple(ctx context.Context, cfg LoadTestConfig, sink Sink, reqCfg RequestConfig, obsCfg ObservabilityConfig, generator *DataGenerator, staticData interface{}) {
+	if cfg.Duration > 0 {
+		fmt.Printf("Running load for %v\n", cfg.Duration)
+	} else {
+		fmt.Printf("Running %d requests\n", cfg.Times)
+	}
+	if cfg.Threads > 1 {
+		fmt.Printf("Using %d concurrent threads\n", cfg.Threads)
+	}
+	if cfg.Rate > 0 {
+		fmt.Printf("Pacing at %d requests/sec\n", cfg.Rate)
 	}
 	fmt.Println("=" + strings.Repeat("=", 50))
 
-	// Shared variables for thread-safe operations
-	var (
-		totalDuration time.Duration
-		successCount  int
-		errorCount    int
-		mu            sync.Mutex
-		wg            sync.WaitGroup
-	)
+	var staticPayload []byte
+	if generator == nil {
+		var err error
+		staticPayload, err = json.Marshal(staticData)
+		if err != nil {
+			fmt.Printf("❌ Error: failed to marshal data: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Channel to distribute work among goroutines
-	workChan := make(chan int, times)
-
-	// Start worker goroutines
-	for i := 0; i < threads; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-
-			for requestNum := range workChan {
-				// Create a copy of the client for this goroutine to avoid race conditions
-				clientCopy := &HTTPClient{
-					URL:      c.URL,
-					Username: c.Username,
-					Password: c.Password,
-					Headers:  make(map[string]string),
+	runOnce := func(cfg LoadTestConfig, stats *loadStats, metrics *PromMetrics) {
+		workChans := make([]chan struct{}, cfg.Threads)
+		for i := range workChans {
+			workChans[i] = make(chan struct{})
+		}
+		var wg sync.WaitGroup
+
+		for i := 0; i < cfg.Threads; i++ {
+			wg.Add(1)
+			go func(workerID int) {
+				defer wg.Done()
+				hist := stats.workerHists[workerID]
+				rng := rand.New(rand.NewSource(cfg.Seed ^ int64(workerID)))
+
+				for range workChans[workerID] {
+					payload := staticPayload
+					if generator != nil {
+						var err error
+						payload, err = generator.GenerateEncoded(rng)
+						if err != nil {
+							atomic.AddInt64(&stats.sent, 1)
+							atomic.AddInt64(&stats.failed, 1)
+							continue
+						}
+					}
+
+					metrics.incInflight()
+					start := time.Now()
+					err := sendWithRetry(ctx, sink, payload, reqCfg, rng)
+					duration := time.Since(start)
+					metrics.decInflight()
+
+					atomic.AddInt64(&stats.sent, 1)
+					status := "success"
+					if err != nil {
+						atomic.AddInt64(&stats.failed, 1)
+						status = "error"
+						var sinkErr *SinkError
+						if errors.As(err, &sinkErr) && sinkErr.StatusCode > 0 {
+							stats.recordStatus(sinkErr.StatusCode)
+							status = strconv.Itoa(sinkErr.StatusCode)
+						}
+					} else {
+						atomic.AddInt64(&stats.success, 1)
+					}
+					metrics.observe(status, duration, len(payload))
+					hist.Record(float64(duration.Nanoseconds()))
 				}
+			}(i)
+		}
 
-				// Copy headers
-				for k, v := range c.Headers {
-					clientCopy.Headers[k] = v
-				}
+		sendChans := make([]chan<- struct{}, len(workChans))
+		for i, c := range workChans {
+			sendChans[i] = c
+		}
 
-				// Generate new data for each request
-				if generator != nil {
-					clientCopy.Data = generator.GenerateData()
-				} else {
-					clientCopy.Data = c.Data
+		if cfg.Duration > 0 {
+			deadline := time.Now().Add(cfg.Duration)
+			dispatch(ctx, sendChans, cfg.Rate, func() bool { return time.Now().Before(deadline) })
+		} else {
+			remaining := cfg.Times
+			dispatch(ctx, sendChans, cfg.Rate, func() bool {
+				if remaining <= 0 {
+					return false
 				}
+				remaining--
+				return true
+			})
+		}
+		for _, c := range workChans {
+			close(c)
+		}
+		wg.Wait()
+	}
+
+	if cfg.Warmup > 0 {
+		fmt.Printf("🔥 Warming up for %v (not counted in results)...\n", cfg.Warmup)
+		// Pass nil metrics: PromMetrics methods are nil-safe no-ops, so warmup
+		// traffic doesn't pollute the /metrics scrape any more than it pollutes
+		// the console/summary stats above.
+		runOnce(LoadTestConfig{Threads: cfg.Threads, Rate: cfg.Rate, Duration: cfg.Warmup, Seed: cfg.Seed}, newLoadStats(cfg.Threads), nil)
+	}
 
-				resp := clientCopy.PostJSON()
-
-				// Thread-safe update of counters and duration
-				mu.Lock()
-				if resp.Error != nil {
-					errorCount++
-					fmt.Printf("\n[Request %d/%d] ❌ Error: %v\n", requestNum, times, resp.Error)
-				} else {
-					successCount++
-					fmt.Printf("\n[Request %d/%d] ✅ Status: %d\n", requestNum, times, resp.StatusCode)
-					fmt.Printf("📄 Response Body: %s\n", resp.Body)
+	stats := newLoadStats(cfg.Threads)
+	start := time.Now()
+
+	var reportDone chan struct{}
+	if cfg.ReportInterval > 0 {
+		reportDone = make(chan struct{})
+		go func() {
+			ticker := time.NewTicker(cfg.ReportInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					printIntermediateReport(time.Since(start), stats)
+				case <-reportDone:
+					return
 				}
-				fmt.Printf("⏱️  Duration: %v\n", resp.Duration)
-				totalDuration += resp.Duration
-				mu.Unlock()
 			}
-		}(i)
+		}()
 	}
 
-	// Send work to the channel
-	for i := 1; i <= times; i++ {
-		workChan <- i
+	runOnce(cfg, stats, obsCfg.Metrics)
+
+	if reportDone != nil {
+		close(reportDone)
 	}
-	close(workChan)
 
-	// Wait for all goroutines to complete
-	wg.Wait()
+	if ctx.Err() != nil {
+		fmt.Println("\n🛑 Interrupted — showing partial results")
+	}
+	totalDuration := time.Since(start)
+	printSummary(cfg, stats, totalDuration)
+
+	if obsCfg.SummaryOut != "" {
+		report := buildSummaryReport(cfg, stats, totalDuration)
+		if err := writeSummaryFile(report, obsCfg.SummaryFormat, obsCfg.SummaryOut); err != nil {
+			fmt.Printf("❌ Error: failed to write summary to %s: %v\n", obsCfg.SummaryOut, err)
+		} else {
+			fmt.Printf("📄 Summary written to %s (%s)\n", obsCfg.SummaryOut, obsCfg.SummaryFormat)
+		}
+	}
+}
+
+// printIntermediateReport prints a one-line progress snapshot for a run still in flight.
+func printIntermediateReport(elapsed time.Duration, stats *loadStats) {
+	sent := atomic.LoadInt64(&stats.sent)
+	success := atomic.LoadInt64(&stats.success)
+	failed := atomic.LoadInt64(&stats.failed)
+	fmt.Printf("⏳ [%v] sent=%d success=%d failed=%d rate=%.1f/s\n",
+		elapsed.Round(time.Second), sent, success, failed, float64(sent)/elapsed.Seconds())
+}
+
+// printSummary prints the final run summary: counts, status breakdown, and latency percentiles.
+func printSummary(cfg LoadTestConfig, stats *loadStats, totalDuration time.Duration) {
+	hist := stats.mergedHistogram()
 
-	// Print summary
 	fmt.Println("\n" + strings.Repeat("=", 50))
 	fmt.Printf("📊 Summary:\n")
-	fmt.Printf("   Total Requests: %d\n", times)
-	fmt.Printf("   Concurrent Threads: %d\n", threads)
-	fmt.Printf("   Successful: %d\n", successCount)
-	fmt.Printf("   Failed: %d\n", errorCount)
-	fmt.Printf("   Total Duration: %v\n", totalDuration)
-	if times > 0 {
-		fmt.Printf("   Average Duration: %v\n", totalDuration/time.Duration(times))
+	fmt.Printf("   Total Requests: %d\n", stats.sent)
+	fmt.Printf("   Concurrent Threads: %d\n", cfg.Threads)
+	fmt.Printf("   Successful: %d\n", stats.success)
+	fmt.Printf("   Failed: %d\n", stats.failed)
+	fmt.Printf("   Wall Duration: %v\n", totalDuration)
+	if stats.sent > 0 {
+		fmt.Printf("   Achieved Rate: %.1f req/s\n", float64(stats.sent)/totalDuration.Seconds())
+	}
+
+	if hist.Count() > 0 {
+		fmt.Printf("   Latency: min=%v p50=%v p90=%v p95=%v p99=%v p999=%v max=%v stddev=%v\n",
+			time.Duration(hist.Min()), time.Duration(hist.Percentile(50)), time.Duration(hist.Percentile(90)),
+			time.Duration(hist.Percentile(95)), time.Duration(hist.Percentile(99)), time.Duration(hist.Percentile(99.9)),
+			time.Duration(hist.Max()), time.Duration(hist.StdDev()))
+	}
+
+	if len(stats.statusCount) > 0 {
+		fmt.Printf("   Error Status Codes:\n")
+		for code, count := range stats.statusCount {
+			fmt.Printf("     %d: %d\n", code, count)
+		}
 	}
 }
 
 func main() {
-	// Initialize random seed
-	rand.Seed(time.Now().UnixNano())
-
 	// Command line flags
 	var (
-		url           = flag.String("url", "http://localhost:5080", "Target URL for POST request")
-		username      = flag.String("user", "root@example.com", "Username for basic auth")
-		password      = flag.String("pass", "Complexpass#123", "Password for basic auth")
-		times         = flag.Int("times", 1, "Number of times to run the request")
-		threads       = flag.Int("threads", 1, "Number of concurrent threads to use")
-		data          = flag.String("data", "", "JSON data to send (leave empty to auto-generate)")
-		header        = flag.String("header", "", "Additional header in format 'key:value' (can be used multiple times)")
-		fieldCount    = flag.Int("fields", 5, "Number of fields to generate in auto-generated data")
-		recordsPerReq = flag.Int("records", 1, "Number of records per request")
-		enableBody    = flag.Bool("body", false, "Enable body field with random size (1KB-200KB)")
+		url            = flag.String("url", "http://localhost:5080", "Target URL for POST request")
+		username       = flag.String("user", "root@example.com", "Username for basic auth")
+		password       = flag.String("pass", "Complexpass#123", "Password for basic auth")
+		times          = flag.Int("times", 1, "Number of times to run the request")
+		threads        = flag.Int("threads", 1, "Number of concurrent threads to use")
+		data           = flag.String("data", "", "JSON data to send (leave empty to auto-generate)")
+		header         = flag.String("header", "", "Additional header in format 'key:value' (can be used multiple times)")
+		fieldCount     = flag.Int("fields", 5, "Number of fields to generate in auto-generated data")
+		recordsPerReq  = flag.Int("records", 1, "Number of records per request")
+		enableBody     = flag.Bool("body", false, "Enable body field with random size (1KB-200KB)")
+		schemaPath     = flag.String("schema", "", "Path to a YAML/JSON schema file describing fields to generate (overrides -fields/-body)")
+		rate           = flag.Int("rate", 0, "Sustained requests/sec to pace at (0 = send as fast as possible)")
+		duration       = flag.Duration("duration", 0, "Wall-clock duration to run for, e.g. 30s (0 = use -times instead)")
+		warmup         = flag.Duration("warmup", 0, "Warmup period to run before measuring results, e.g. 5s")
+		reportInterval = flag.Duration("report-interval", 0, "Print an intermediate report every interval, e.g. 5s (0 = disabled)")
+		sinkURL        = flag.String("sink", "", "Output sink URL: kafka://broker/topic, nats://host/subject, grpc://host/pkg.Service/Method, file://out.ndjson, stdout:// (default: HTTP POST to -url)")
+		encodingFlag   = flag.String("encoding", "json", "Payload encoding for auto-generated data: json, ndjson, es-bulk, loki, otlp-logs")
+		indexName      = flag.String("index", "data-generator", "_index name for -encoding es-bulk (supports {date})")
+		timeout        = flag.Duration("timeout", 30*time.Second, "Per-request timeout")
+		retries        = flag.Int("retries", 0, "Number of retries for transient errors")
+		retryBackoff   = flag.Duration("retry-backoff", 100*time.Millisecond, "Base backoff between retries (exponential, with jitter)")
+		retryOn        = flag.String("retry-on", "5xx,timeout", "Comma-separated transient error classes to retry: 5xx, timeout")
+		summaryFormat  = flag.String("summary-format", "text", "Format for -summary-out: json, csv, or text")
+		summaryOut     = flag.String("summary-out", "", "Write a machine-readable run summary to this path (requires a format via -summary-format)")
+		metricsListen  = flag.String("metrics-listen", "", "Address to serve Prometheus metrics on, e.g. :9090 (empty = disabled)")
+		seed           = flag.Int64("seed", 0, "Seed for reproducible data generation; each worker derives its own seed from this (unset = random seed per run; -seed 0 is a valid explicit seed)")
 	)
 	flag.Parse()
 
+	var schema *Schema
+	if *schemaPath != "" {
+		var err error
+		schema, err = LoadSchema(*schemaPath)
+		if err != nil {
+			fmt.Printf("❌ Error: failed to load schema: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Validate required parameters
 	if *url == "" {
 		fmt.Println("❌ Error: URL is required")
@@ -425,7 +655,7 @@ func main() {
 	}
 
 	// Create HTTP client
-	client := NewHTTPClient(*url, *username, *password, jsonData)
+	client := NewHTTPClient(*url, *username, *password)
 
 	// Add custom headers
 	if *header != "" {
@@ -437,6 +667,74 @@ func main() {
 		}
 	}
 
+	// flag.Visit only reports flags the user actually passed, so "-seed 0"
+	// (a deliberate, reproducible seed) can be told apart from not passing
+	// -seed at all (which should still randomize).
+	seedSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	baseSeed := *seed
+	if !seedSet {
+		baseSeed = time.Now().UnixNano()
+	}
+
+	loadCfg := LoadTestConfig{
+		Times:          *times,
+		Threads:        *threads,
+		Rate:           *rate,
+		Duration:       *duration,
+		Warmup:         *warmup,
+		ReportInterval: *reportInterval,
+		Seed:           baseSeed,
+	}
+
+	reqCfg := RequestConfig{
+		Timeout:    *timeout,
+		MaxRetries: *retries,
+		Backoff:    *retryBackoff,
+		RetryOn:    parseRetryOn(*retryOn),
+	}
+
+	// Cancel in-flight requests on SIGINT instead of hard-exiting mid-batch.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	contentType := Encoding(*encodingFlag).ContentType()
+
+	// Build the sink: -sink picks Kafka/NATS/gRPC/file/stdout, otherwise we
+	// fall back to HTTP POST against -url.
+	var sink Sink
+	if *sinkURL != "" {
+		s, err := NewSink(*sinkURL, client, contentType)
+		if err != nil {
+			fmt.Printf("❌ Error: failed to build sink: %v\n", err)
+			os.Exit(1)
+		}
+		sink = s
+	} else {
+		sink = newHTTPSink(client, contentType)
+	}
+	defer sink.Close()
+
+	var metrics *PromMetrics
+	if *metricsListen != "" {
+		metrics = NewPromMetrics()
+		go func() {
+			if err := metrics.Serve(*metricsListen); err != nil && err != http.ErrServerClosed {
+				fmt.Printf("❌ Error: metrics server on %s failed: %v\n", *metricsListen, err)
+			}
+		}()
+		fmt.Printf("📈 Serving Prometheus metrics on %s/metrics\n", *metricsListen)
+	}
+	obsCfg := ObservabilityConfig{
+		Metrics:       metrics,
+		SummaryFormat: *summaryFormat,
+		SummaryOut:    *summaryOut,
+	}
+
 	// Run the requests
 	if *data == "" {
 		// Auto-generate data for each request
@@ -444,10 +742,13 @@ func main() {
 			FieldCount:    *fieldCount,
 			RecordsPerReq: *recordsPerReq,
 			EnableBody:    *enableBody,
+			Schema:        schema,
+			Encoding:      *encodingFlag,
+			IndexName:     *indexName,
 		}
-		client.RunMultiple(*times, *threads, generator)
+		RunMultiple(ctx, loadCfg, sink, reqCfg, obsCfg, generator, nil)
 	} else {
 		// Use provided data (same data for all requests)
-		client.RunMultiple(*times, *threads, nil)
+		RunMultiple(ctx, loadCfg, sink, reqCfg, obsCfg, nil, jsonData)
 	}
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PromMetrics holds the Prometheus collectors a run updates as it sends
+// requests. A nil *PromMetrics is valid and every method is a no-op, so
+// callers don't need to guard every call site behind "if metrics != nil".
+type PromMetrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration prometheus.Histogram
+	inflight        prometheus.Gauge
+	bytesSent       prometheus.Counter
+}
+
+// NewPromMetrics registers and returns the collectors exposed on /metrics.
+func NewPromMetrics() *PromMetrics {
+	m := &PromMetrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "requests_total",
+			Help: "Total requests sent, labeled by outcome status.",
+		}, []string{"status"}),
+		requestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "request_duration_seconds",
+			Help:    "Request latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		inflight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "inflight_requests",
+			Help: "Requests currently in flight.",
+		}),
+		bytesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bytes_sent_total",
+			Help: "Total payload bytes sent.",
+		}),
+	}
+	prometheus.MustRegister(m.requestsTotal, m.requestDuration, m.inflight, m.bytesSent)
+	return m
+}
+
+// Serve starts a blocking /metrics HTTP server; run it in its own goroutine.
+func (m *PromMetrics) Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, mux)
+}
+
+func (m *PromMetrics) incInflight() {
+	if m != nil {
+		m.inflight.Inc()
+	}
+}
+
+func (m *PromMetrics) decInflight() {
+	if m != nil {
+		m.inflight.Dec()
+	}
+}
+
+// observe records one completed request: its outcome label ("success", an
+// HTTP status code, or "error"), latency, and payload size.
+func (m *PromMetrics) observe(status string, duration time.Duration, payloadBytes int) {
+	if m == nil {
+		return
+	}
+	m.requestsTotal.WithLabelValues(status).Inc()
+	m.requestDuration.Observe(duration.Seconds())
+	m.bytesSent.Add(float64(payloadBytes))
+}
+
+// SummaryReport is the structured form of printSummary's console output, for
+// machine consumption (CI assertions, dashboards) via -summary-format/-summary-out.
+type SummaryReport struct {
+	TotalRequests    int64            `json:"total_requests"`
+	Threads          int              `json:"threads"`
+	Successful       int64            `json:"successful"`
+	Failed           int64            `json:"failed"`
+	WallDuration     string           `json:"wall_duration"`
+	AchievedRate     float64          `json:"achieved_rate"`
+	LatencyMinMs     float64          `json:"latency_min_ms"`
+	LatencyP50Ms     float64          `json:"latency_p50_ms"`
+	LatencyP90Ms     float64          `json:"latency_p90_ms"`
+	LatencyP95Ms     float64          `json:"latency_p95_ms"`
+	LatencyP99Ms     float64          `json:"latency_p99_ms"`
+	LatencyP999Ms    float64          `json:"latency_p999_ms"`
+	LatencyMaxMs     float64          `json:"latency_max_ms"`
+	LatencyStdDevMs  float64          `json:"latency_stddev_ms"`
+	ErrorStatusCodes map[string]int64 `json:"error_status_codes,omitempty"`
+}
+
+func msFromNanos(nanos float64) float64 { return nanos / 1e6 }
+
+// buildSummaryReport turns the run's stats into the exportable report shape.
+func buildSummaryReport(cfg LoadTestConfig, stats *loadStats, totalDuration time.Duration) *SummaryReport {
+	hist := stats.mergedHistogram()
+
+	report := &SummaryReport{
+		TotalRequests: stats.sent,
+		Threads:       cfg.Threads,
+		Successful:    stats.success,
+		Failed:        stats.failed,
+		WallDuration:  totalDuration.String(),
+	}
+	if stats.sent > 0 {
+		report.AchievedRate = float64(stats.sent) / totalDuration.Seconds()
+	}
+	if hist.Count() > 0 {
+		report.LatencyMinMs = msFromNanos(hist.Min())
+		report.LatencyP50Ms = msFromNanos(hist.Percentile(50))
+		report.LatencyP90Ms = msFromNanos(hist.Percentile(90))
+		report.LatencyP95Ms = msFromNanos(hist.Percentile(95))
+		report.LatencyP99Ms = msFromNanos(hist.Percentile(99))
+		report.LatencyP999Ms = msFromNanos(hist.Percentile(99.9))
+		report.LatencyMaxMs = msFromNanos(hist.Max())
+		report.LatencyStdDevMs = msFromNanos(hist.StdDev())
+	}
+	if len(stats.statusCount) > 0 {
+		report.ErrorStatusCodes = make(map[string]int64, len(stats.statusCount))
+		for code, count := range stats.statusCount {
+			report.ErrorStatusCodes[strconv.Itoa(code)] = count
+		}
+	}
+	return report
+}
+
+// writeSummaryFile renders report in the given format ("json", "csv", or
+// "text") and writes it to path, creating or truncating the file.
+func writeSummaryFile(report *SummaryReport, format, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file: %v", err)
+	}
+	defer f.Close()
+
+	switch format {
+	case "", "text":
+		return writeSummaryText(f, report)
+	case "json":
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "csv":
+		return writeSummaryCSV(f, report)
+	default:
+		return fmt.Errorf("unknown summary format %q (want json, csv, or text)", format)
+	}
+}
+
+func writeSummaryText(w io.Writer, r *SummaryReport) error {
+	_, err := fmt.Fprintf(w,
+		"total_requests=%d threads=%d successful=%d failed=%d wall_duration=%s achieved_rate=%.1f "+
+			"latency_p50_ms=%.3f latency_p90_ms=%.3f latency_p95_ms=%.3f latency_p99_ms=%.3f latency_p999_ms=%.3f\n",
+		r.TotalRequests, r.Threads, r.Successful, r.Failed, r.WallDuration, r.AchievedRate,
+		r.LatencyP50Ms, r.LatencyP90Ms, r.LatencyP95Ms, r.LatencyP99Ms, r.LatencyP999Ms)
+	return err
+}
+
+func writeSummaryCSV(w io.Writer, r *SummaryReport) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{
+		"total_requests", "threads", "successful", "failed", "wall_duration", "achieved_rate",
+		"latency_min_ms", "latency_p50_ms", "latency_p90_ms", "latency_p95_ms",
+		"latency_p99_ms", "latency_p999_ms", "latency_max_ms", "latency_stddev_ms",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	row := []string{
+		strconv.FormatInt(r.TotalRequests, 10),
+		strconv.Itoa(r.Threads),
+		strconv.FormatInt(r.Successful, 10),
+		strconv.FormatInt(r.Failed, 10),
+		r.WallDuration,
+		strconv.FormatFloat(r.AchievedRate, 'f', 1, 64),
+		strconv.FormatFloat(r.LatencyMinMs, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyP50Ms, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyP90Ms, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyP95Ms, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyP99Ms, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyP999Ms, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyMaxMs, 'f', 3, 64),
+		strconv.FormatFloat(r.LatencyStdDevMs, 'f', 3, 64),
+	}
+	return cw.Write(row)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+// RequestConfig controls the per-request deadline and retry behavior shared
+// by every worker in RunMultiple.
+type RequestConfig struct {
+	Timeout    time.Duration
+	MaxRetries int
+	Backoff    time.Duration
+	RetryOn    map[string]bool // "5xx", "timeout"; empty means retry on any error
+}
+
+// parseRetryOn turns a "5xx,timeout" flag value into the lookup map RequestConfig wants.
+func parseRetryOn(spec string) map[string]bool {
+	classes := make(map[string]bool)
+	for _, c := range strings.Split(spec, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			classes[c] = true
+		}
+	}
+	return classes
+}
+
+// isRetryable reports whether err belongs to one of the transient classes
+// enabled in retryOn. An empty retryOn treats every error as retryable.
+func isRetryable(err error, retryOn map[string]bool) bool {
+	if err == nil {
+		return false
+	}
+	if len(retryOn) == 0 {
+		return true
+	}
+
+	if retryOn["5xx"] {
+		var sinkErr *SinkError
+		if errors.As(err, &sinkErr) && sinkErr.StatusCode >= 500 {
+			return true
+		}
+	}
+	if retryOn["timeout"] {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffWithJitter returns a delay that grows exponentially with attempt
+// (0-indexed) around base, plus up to 50% jitter to avoid retry storms. rng
+// is the caller's seeded *rand.Rand, so backoff timing is reproducible
+// alongside generated payloads given the same -seed.
+func backoffWithJitter(rng *rand.Rand, base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base * time.Duration(int64(1)<<uint(attempt))
+	jitter := time.Duration(rng.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// sendWithRetry calls sink.Send, retrying transient failures per cfg with
+// exponential backoff and jitter drawn from rng. Each attempt gets its own
+// cfg.Timeout deadline derived from ctx; canceling ctx (e.g. on SIGINT)
+// aborts immediately instead of waiting out the remaining retries.
+func sendWithRetry(ctx context.Context, sink Sink, payload []byte, cfg RequestConfig, rng *rand.Rand) error {
+	var err error
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		attemptCtx := ctx
+		var cancel context.CancelFunc
+		if cfg.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		}
+		err = sink.Send(attemptCtx, payload)
+		if cancel != nil {
+			cancel()
+		}
+
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if attempt == cfg.MaxRetries || !isRetryable(err, cfg.RetryOn) {
+			return err
+		}
+
+		select {
+		case <-time.After(backoffWithJitter(rng, cfg.Backoff, attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldProvider generates a single field's value for a schema-driven record.
+type FieldProvider interface {
+	// Name returns the JSON field name this provider fills in.
+	Name() string
+	// Generate returns the next value for this field, drawing any randomness
+	// it needs from rng so a run is reproducible given the same -seed.
+	Generate(rng *rand.Rand) interface{}
+}
+
+// SchemaField is the on-disk (YAML/JSON) description of one field.
+type SchemaField struct {
+	Name    string                 `json:"name" yaml:"name"`
+	Type    string                 `json:"type" yaml:"type"`
+	Params  map[string]interface{} `json:"params" yaml:"params"`
+	Fields  []SchemaField          `json:"fields" yaml:"fields"` // for type: object
+	Elem    *SchemaField           `json:"elem" yaml:"elem"`     // for type: array
+	Count   int                    `json:"count" yaml:"count"`   // for type: array
+	IsLabel bool                   `json:"label" yaml:"label"`   // loki stream labels, see chunk0-4
+}
+
+// Schema is a parsed schema file: an ordered list of field providers, plus
+// which field names were tagged `label: true` (used by the loki encoding).
+type Schema struct {
+	Fields []FieldProvider
+	Labels map[string]bool
+}
+
+// LoadSchema reads a YAML or JSON schema file (selected by extension) and
+// builds the FieldProvider slice it describes.
+func LoadSchema(path string) (*Schema, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %v", err)
+	}
+
+	var fields []SchemaField
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var doc struct {
+			Fields []SchemaField `yaml:"fields"`
+		}
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML schema: %v", err)
+		}
+		fields = doc.Fields
+	case ".json":
+		var doc struct {
+			Fields []SchemaField `json:"fields"`
+		}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON schema: %v", err)
+		}
+		fields = doc.Fields
+	default:
+		return nil, fmt.Errorf("unsupported schema extension %q (want .yaml, .yml or .json)", ext)
+	}
+
+	providers := make([]FieldProvider, 0, len(fields))
+	labels := make(map[string]bool)
+	for _, f := range fields {
+		p, err := newFieldProvider(f)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", f.Name, err)
+		}
+		providers = append(providers, p)
+		if f.IsLabel {
+			labels[f.Name] = true
+		}
+	}
+
+	return &Schema{Fields: providers, Labels: labels}, nil
+}
+
+// Generate builds one record using every provider in the schema, in order,
+// drawing all randomness from rng so a run is reproducible given the same -seed.
+func (s *Schema) Generate(rng *rand.Rand) map[string]interface{} {
+	record := make(map[string]interface{}, len(s.Fields))
+	for _, p := range s.Fields {
+		record[p.Name()] = p.Generate(rng)
+	}
+	return record
+}
+
+// newFieldProvider builds the FieldProvider described by a single schema field.
+func newFieldProvider(f SchemaField) (FieldProvider, error) {
+	switch strings.ToLower(f.Type) {
+	case "string":
+		length := paramInt(f.Params, "length", 10)
+		return &stringFieldProvider{name: f.Name, length: length}, nil
+	case "int", "integer":
+		min := paramInt(f.Params, "min", 0)
+		max := paramInt(f.Params, "max", 10000)
+		return &intFieldProvider{name: f.Name, min: min, max: max}, nil
+	case "float":
+		min := paramFloat(f.Params, "min", 0)
+		max := paramFloat(f.Params, "max", 1)
+		return &floatFieldProvider{name: f.Name, min: min, max: max}, nil
+	case "bool", "boolean":
+		return &boolFieldProvider{name: f.Name}, nil
+	case "base64-blob":
+		sizeKB := paramInt(f.Params, "size_kb", 1)
+		return &base64BlobFieldProvider{name: f.Name, sizeKB: sizeKB}, nil
+	case "timestamp":
+		jitter := paramDuration(f.Params, "jitter", 0)
+		layout := paramString(f.Params, "layout", time.RFC3339)
+		return &timestampFieldProvider{name: f.Name, jitter: jitter, layout: layout}, nil
+	case "ip", "internet.ipv4":
+		return &ipFieldProvider{name: f.Name}, nil
+	case "uuid":
+		return &uuidFieldProvider{name: f.Name}, nil
+	case "counter":
+		start := paramInt(f.Params, "start", 0)
+		step := paramInt(f.Params, "step", 1)
+		return &counterFieldProvider{name: f.Name, next: int64(start), step: int64(step)}, nil
+	case "enum":
+		values, _ := f.Params["values"].([]interface{})
+		weights, _ := f.Params["weights"].([]interface{})
+		return newEnumFieldProvider(f.Name, values, weights)
+	case "regex":
+		pattern := paramString(f.Params, "pattern", "")
+		if pattern == "" {
+			return nil, fmt.Errorf("regex field requires a non-empty params.pattern")
+		}
+		return newRegexFieldProvider(f.Name, pattern)
+	case "person.email":
+		return &categoryFieldProvider{name: f.Name, gen: generatePersonEmail}, nil
+	case "object":
+		children := make([]FieldProvider, 0, len(f.Fields))
+		for _, child := range f.Fields {
+			cp, err := newFieldProvider(child)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, cp)
+		}
+		return &objectFieldProvider{name: f.Name, children: children}, nil
+	case "array":
+		if f.Elem == nil {
+			return nil, fmt.Errorf("array field requires an elem definition")
+		}
+		count := f.Count
+		if count <= 0 {
+			count = 1
+		}
+		elemName := f.Elem.Name
+		if elemName == "" {
+			elemName = f.Name
+		}
+		elem, err := newFieldProvider(SchemaField{Name: elemName, Type: f.Elem.Type, Params: f.Elem.Params, Fields: f.Elem.Fields, Elem: f.Elem.Elem, Count: f.Elem.Count})
+		if err != nil {
+			return nil, err
+		}
+		return &arrayFieldProvider{name: f.Name, elem: elem, count: count}, nil
+	default:
+		return nil, fmt.Errorf("unknown field type %q", f.Type)
+	}
+}
+
+func paramInt(params map[string]interface{}, key string, def int) int {
+	if v, ok := params[key]; ok {
+		switch n := v.(type) {
+		case int:
+			return n
+		case float64:
+			return int(n)
+		case string:
+			if i, err := strconv.Atoi(n); err == nil {
+				return i
+			}
+		}
+	}
+	return def
+}
+
+func paramFloat(params map[string]interface{}, key string, def float64) float64 {
+	if v, ok := params[key]; ok {
+		switch n := v.(type) {
+		case float64:
+			return n
+		case int:
+			return float64(n)
+		}
+	}
+	return def
+}
+
+// weightFloat converts one enum weight to float64. Weights come from
+// params.weights, decoded from either JSON (always float64) or YAML (plain
+// integers decode to int), so both must be accepted.
+func weightFloat(w interface{}) float64 {
+	switch n := w.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	}
+	return 0
+}
+
+func paramString(params map[string]interface{}, key, def string) string {
+	if v, ok := params[key]; ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return def
+}
+
+func paramDuration(params map[string]interface{}, key string, def time.Duration) time.Duration {
+	if s, ok := params[key].(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+// stringFieldProvider emits a random alphanumeric string of a fixed length.
+type stringFieldProvider struct {
+	name   string
+	length int
+}
+
+func (p *stringFieldProvider) Name() string { return p.name }
+func (p *stringFieldProvider) Generate(rng *rand.Rand) interface{} {
+	return generateRandomString(rng, p.length)
+}
+
+// intFieldProvider emits a random integer in [min, max).
+type intFieldProvider struct {
+	name     string
+	min, max int
+}
+
+func (p *intFieldProvider) Name() string { return p.name }
+func (p *intFieldProvider) Generate(rng *rand.Rand) interface{} {
+	if p.max <= p.min {
+		return p.min
+	}
+	return p.min + rng.Intn(p.max-p.min)
+}
+
+// floatFieldProvider emits a random float in [min, max).
+type floatFieldProvider struct {
+	name     string
+	min, max float64
+}
+
+func (p *floatFieldProvider) Name() string { return p.name }
+func (p *floatFieldProvider) Generate(rng *rand.Rand) interface{} {
+	return p.min + rng.Float64()*(p.max-p.min)
+}
+
+// boolFieldProvider emits a random boolean.
+type boolFieldProvider struct {
+	name string
+}
+
+func (p *boolFieldProvider) Name() string { return p.name }
+func (p *boolFieldProvider) Generate(rng *rand.Rand) interface{} { return rng.Intn(2) == 1 }
+
+// base64BlobFieldProvider emits base64-encoded random bytes, mirroring generateRandomBody.
+type base64BlobFieldProvider struct {
+	name   string
+	sizeKB int
+}
+
+func (p *base64BlobFieldProvider) Name() string { return p.name }
+func (p *base64BlobFieldProvider) Generate(rng *rand.Rand) interface{} {
+	return generateRandomBody(rng, p.sizeKB)
+}
+
+// timestampFieldProvider emits the current time, optionally jittered by +/- jitter.
+type timestampFieldProvider struct {
+	name   string
+	jitter time.Duration
+	layout string
+}
+
+func (p *timestampFieldProvider) Name() string { return p.name }
+func (p *timestampFieldProvider) Generate(rng *rand.Rand) interface{} {
+	t := time.Now()
+	if p.jitter > 0 {
+		offset := time.Duration(rng.Int63n(int64(2*p.jitter))) - p.jitter
+		t = t.Add(offset)
+	}
+	return t.Format(p.layout)
+}
+
+// ipFieldProvider emits a random IPv4 address.
+type ipFieldProvider struct {
+	name string
+}
+
+func (p *ipFieldProvider) Name() string { return p.name }
+func (p *ipFieldProvider) Generate(rng *rand.Rand) interface{} { return generateRandomIP(rng) }
+
+// uuidFieldProvider emits a random RFC 4122 version-4 UUID.
+type uuidFieldProvider struct {
+	name string
+}
+
+func (p *uuidFieldProvider) Name() string { return p.name }
+func (p *uuidFieldProvider) Generate(rng *rand.Rand) interface{} {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(rng.Intn(256))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// counterFieldProvider emits a monotonically increasing integer. next is
+// advanced with atomic.AddInt64 since a single Schema (and its providers) is
+// shared across every -threads worker goroutine.
+type counterFieldProvider struct {
+	name string
+	next int64
+	step int64
+}
+
+func (p *counterFieldProvider) Name() string { return p.name }
+func (p *counterFieldProvider) Generate(rng *rand.Rand) interface{} {
+	return atomic.AddInt64(&p.next, p.step) - p.step
+}
+
+// enumFieldProvider picks among a fixed set of values, optionally weighted.
+type enumFieldProvider struct {
+	name    string
+	values  []interface{}
+	weights []float64
+	total   float64
+}
+
+func newEnumFieldProvider(name string, values, weights []interface{}) (*enumFieldProvider, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("enum field requires a non-empty params.values")
+	}
+	p := &enumFieldProvider{name: name, values: values}
+	if len(weights) == 0 {
+		return p, nil
+	}
+	if len(weights) != len(values) {
+		return nil, fmt.Errorf("enum field has %d values but %d weights", len(values), len(weights))
+	}
+	p.weights = make([]float64, len(weights))
+	for i, w := range weights {
+		f := weightFloat(w)
+		p.weights[i] = f
+		p.total += f
+	}
+	return p, nil
+}
+
+func (p *enumFieldProvider) Name() string { return p.name }
+func (p *enumFieldProvider) Generate(rng *rand.Rand) interface{} {
+	if p.weights == nil {
+		return p.values[rng.Intn(len(p.values))]
+	}
+	r := rng.Float64() * p.total
+	var acc float64
+	for i, w := range p.weights {
+		acc += w
+		if r < acc {
+			return p.values[i]
+		}
+	}
+	return p.values[len(p.values)-1]
+}
+
+// categoryFieldProvider wraps a faker-style category generator function
+// (e.g. person.email, internet.ipv4) behind the FieldProvider interface.
+type categoryFieldProvider struct {
+	name string
+	gen  func(rng *rand.Rand) string
+}
+
+func (p *categoryFieldProvider) Name() string { return p.name }
+func (p *categoryFieldProvider) Generate(rng *rand.Rand) interface{} { return p.gen(rng) }
+
+func generatePersonEmail(rng *rand.Rand) string {
+	return strings.ToLower(generateRandomString(rng, 8)) + "@" + strings.ToLower(generateRandomString(rng, 6)) + ".com"
+}
+
+// regexFieldProvider emits strings matching a small regex subset: literal
+// characters, [abc]/[a-z] character classes, and {n}/{n,m} repeat counts.
+// It is not a full regex engine; it covers the patterns commonly used to
+// shape IDs and codes (e.g. "ORD-[0-9]{6}").
+type regexFieldProvider struct {
+	name string
+	toks []regexToken
+}
+
+type regexToken struct {
+	literal string
+	class   []rune
+	min     int
+	max     int
+}
+
+func newRegexFieldProvider(name, pattern string) (*regexFieldProvider, error) {
+	toks, err := parseRegexPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern %q: %v", pattern, err)
+	}
+	return &regexFieldProvider{name: name, toks: toks}, nil
+}
+
+func (p *regexFieldProvider) Name() string { return p.name }
+func (p *regexFieldProvider) Generate(rng *rand.Rand) interface{} {
+	var sb strings.Builder
+	for _, t := range p.toks {
+		n := t.min
+		if t.max > t.min {
+			n += rng.Intn(t.max - t.min + 1)
+		}
+		if t.class != nil {
+			for i := 0; i < n; i++ {
+				sb.WriteRune(t.class[rng.Intn(len(t.class))])
+			}
+		} else {
+			for i := 0; i < n; i++ {
+				sb.WriteString(t.literal)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// parseRegexPattern tokenizes the subset of regex syntax regexFieldProvider supports.
+func parseRegexPattern(pattern string) ([]regexToken, error) {
+	var toks []regexToken
+	r := []rune(pattern)
+	i := 0
+	for i < len(r) {
+		var tok regexToken
+		switch {
+		case r[i] == '[':
+			end := strings.IndexRune(string(r[i:]), ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class, err := expandCharClass(string(r[i+1 : i+end]))
+			if err != nil {
+				return nil, err
+			}
+			tok = regexToken{class: class, min: 1, max: 1}
+			i += end + 1
+		default:
+			tok = regexToken{literal: string(r[i]), min: 1, max: 1}
+			i++
+		}
+
+		if i < len(r) && r[i] == '{' {
+			end := strings.IndexRune(string(r[i:]), '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated repeat count")
+			}
+			spec := string(r[i+1 : i+end])
+			min, max, err := parseRepeatSpec(spec)
+			if err != nil {
+				return nil, err
+			}
+			tok.min, tok.max = min, max
+			i += end + 1
+		}
+
+		toks = append(toks, tok)
+	}
+	return toks, nil
+}
+
+func parseRepeatSpec(spec string) (int, int, error) {
+	parts := strings.SplitN(spec, ",", 2)
+	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid repeat count %q", spec)
+	}
+	if len(parts) == 1 {
+		return min, min, nil
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid repeat count %q", spec)
+	}
+	return min, max, nil
+}
+
+// objectFieldProvider emits a nested JSON object built from child providers.
+type objectFieldProvider struct {
+	name     string
+	children []FieldProvider
+}
+
+func (p *objectFieldProvider) Name() string { return p.name }
+func (p *objectFieldProvider) Generate(rng *rand.Rand) interface{} {
+	obj := make(map[string]interface{}, len(p.children))
+	for _, c := range p.children {
+		obj[c.Name()] = c.Generate(rng)
+	}
+	return obj
+}
+
+// arrayFieldProvider emits a fixed-length array of values from a single element provider.
+type arrayFieldProvider struct {
+	name  string
+	elem  FieldProvider
+	count int
+}
+
+func (p *arrayFieldProvider) Name() string { return p.name }
+func (p *arrayFieldProvider) Generate(rng *rand.Rand) interface{} {
+	arr := make([]interface{}, p.count)
+	for i := range arr {
+		arr[i] = p.elem.Generate(rng)
+	}
+	return arr
+}
+
+// expandCharClass turns "a-z0-9_" into the runes it denotes.
+func expandCharClass(spec string) ([]rune, error) {
+	var out []rune
+	r := []rune(spec)
+	for i := 0; i < len(r); i++ {
+		if i+2 < len(r) && r[i+1] == '-' {
+			if r[i] > r[i+2] {
+				return nil, fmt.Errorf("invalid character range %c-%c", r[i], r[i+2])
+			}
+			for c := r[i]; c <= r[i+2]; c++ {
+				out = append(out, c)
+			}
+			i += 2
+			continue
+		}
+		out = append(out, r[i])
+	}
+	return out, nil
+}